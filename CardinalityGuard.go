@@ -0,0 +1,153 @@
+package metrics
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultOverflowValue is substituted for every label value of a tuple once
+// its metric's cardinality cap has been reached.
+const DefaultOverflowValue = "__overflow__"
+
+// cardinalityOverflowMetricName is a fixed, un-namespaced name so that the
+// overflow counter is identifiable the same way across every service that
+// uses this library, regardless of that service's own Namespace.
+const cardinalityOverflowMetricName = "go_metrics_cardinality_overflow_total"
+
+// cardinalitySet tracks the distinct label-value tuples seen so far for one
+// CountLabels/HistogramVec/SummaryVec metric.
+type cardinalitySet struct {
+	mu   sync.Mutex
+	seen map[string]struct{}
+}
+
+// WithMaxCardinality caps the number of distinct label-value tuples a single
+// CountLabels, HistogramVec or SummaryVec metric will track. Once the cap is
+// reached, further unseen tuples are recorded under the overflow value
+// instead of their real label values. Zero (the default) falls back to the
+// process-wide Metrics.MaxCardinality, which itself leaves cardinality
+// unbounded when zero.
+func WithMaxCardinality(max int) Option {
+	return func(o *registrationOptions) {
+		o.maxCardinality = max
+	}
+}
+
+// WithOverflowValue overrides DefaultOverflowValue (or Metrics.OverflowValue)
+// for the metric being registered by this call.
+func WithOverflowValue(value string) Option {
+	return func(o *registrationOptions) {
+		o.overflowValue = value
+	}
+}
+
+// OnCardinalityExceeded registers a callback invoked every time a label-value
+// tuple is replaced with the overflow value because its metric's cardinality
+// cap was reached. It is meant for callers that want to log or alert; only
+// one callback can be registered at a time. Over-cap tuples are never added
+// to the tracked set, so the callback runs again on every later observation
+// of the same over-cap tuple.
+func (m *Metrics) OnCardinalityExceeded(callback func(key string, labels, values []string)) {
+	m.cardinalityCallbackMutex.Lock()
+	defer m.cardinalityCallbackMutex.Unlock()
+	m.cardinalityCallback = callback
+}
+
+// Cardinality returns the number of distinct label-value tuples currently
+// tracked for the specified metric.
+func (m *Metrics) Cardinality(subsystem, name string) int {
+	key := fmt.Sprintf("%s/%s", subsystem, name)
+	v, ok := m.cardinalityIndex.Load(key)
+	if !ok {
+		return 0
+	}
+	set := v.(*cardinalitySet)
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	return len(set.seen)
+}
+
+// GuardLabelValues applies this Metrics instance's cardinality cap to values for the specified key and
+// labels, exactly as CountLabels/AddHistogramVec/AddSummaryVec do internally. It is exported for adapters
+// (such as the abstract package) that maintain their own vector collectors outside of Metrics' built-in
+// maps but still want the same cardinality guarding; opts can override the cap the same way as elsewhere.
+func (m *Metrics) GuardLabelValues(key string, labels, values []string, opts ...Option) []string {
+	ro := applyOptions(opts)
+	max := ro.maxCardinality
+	if max == 0 {
+		max = m.MaxCardinality
+	}
+	overflow := ro.overflowValue
+	if overflow == "" {
+		overflow = m.OverflowValue
+	}
+	return m.guardCardinality(key, labels, values, max, overflow)
+}
+
+// guardCardinality enforces max on key's tracked tuples: values is returned
+// unchanged for tuples already seen, or while under the cap (or when max is
+// zero, i.e. unbounded); once the cap is reached, a new tuple is replaced
+// wholesale with overflow and counted on cardinalityOverflowMetricName.
+func (m *Metrics) guardCardinality(key string, labels, values []string, max int, overflow string) []string {
+	if max <= 0 || len(values) == 0 {
+		return values
+	}
+	if overflow == "" {
+		overflow = DefaultOverflowValue
+	}
+
+	v, _ := m.cardinalityIndex.LoadOrStore(key, &cardinalitySet{seen: make(map[string]struct{})})
+	set := v.(*cardinalitySet)
+	tuple := strings.Join(values, labelTupleSep)
+
+	set.mu.Lock()
+	_, known := set.seen[tuple]
+	if !known && len(set.seen) >= max {
+		set.mu.Unlock()
+		m.recordCardinalityOverflow(key, labels, values)
+		overflowed := make([]string, len(values))
+		for i := range overflowed {
+			overflowed[i] = overflow
+		}
+		return overflowed
+	}
+	if !known {
+		set.seen[tuple] = struct{}{}
+	}
+	set.mu.Unlock()
+	return values
+}
+
+func (m *Metrics) recordCardinalityOverflow(key string, labels, values []string) {
+	m.cardinalityOverflowMutex.Lock()
+	if m.cardinalityOverflowCounter == nil {
+		counter := prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: cardinalityOverflowMetricName,
+			Help: "Total number of label-value tuples replaced with the overflow value after a metric's cardinality cap was reached.",
+		}, []string{"metric"})
+		// The name is intentionally fixed (not namespaced), so it collides
+		// across every Metrics instance sharing a Registerer (e.g. the
+		// process-wide prometheus.DefaultRegisterer); reuse the existing
+		// collector instead of panicking in that case.
+		if err := m.register(counter); err != nil {
+			if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+				counter = are.ExistingCollector.(*prometheus.CounterVec)
+			} else {
+				panic(err)
+			}
+		}
+		m.cardinalityOverflowCounter = counter
+	}
+	m.cardinalityOverflowCounter.WithLabelValues(key).Inc()
+	m.cardinalityOverflowMutex.Unlock()
+
+	m.cardinalityCallbackMutex.RLock()
+	callback := m.cardinalityCallback
+	m.cardinalityCallbackMutex.RUnlock()
+	if callback != nil {
+		callback(key, labels, values)
+	}
+}
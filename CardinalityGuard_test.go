@@ -0,0 +1,65 @@
+package metrics // white-box test
+
+import (
+	"testing"
+
+	"github.com/Travix-International/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics_CountLabelsCardinalityCap(t *testing.T) {
+	log, _ := logger.New(make(map[string]string))
+	sut := NewMetricsWithRegistry("ns", log, prometheus.NewRegistry())
+
+	sut.CountLabels("cap", "requests", "requests", []string{"route"}, []string{"/a"}, WithMaxCardinality(1))
+	sut.CountLabels("cap", "requests", "requests", []string{"route"}, []string{"/a"}, WithMaxCardinality(1))
+	sut.CountLabels("cap", "requests", "requests", []string{"route"}, []string{"/b"}, WithMaxCardinality(1))
+
+	assert.Equal(t, 1, sut.Cardinality("cap", "requests"))
+}
+
+func TestMetrics_CountLabelsOverflowCallback(t *testing.T) {
+	log, _ := logger.New(make(map[string]string))
+	sut := NewMetricsWithRegistry("ns", log, prometheus.NewRegistry())
+	sut.MaxCardinality = 1
+
+	var overflowedKey string
+	var overflowedValues []string
+	sut.OnCardinalityExceeded(func(key string, labels, values []string) {
+		overflowedKey = key
+		overflowedValues = values
+	})
+
+	sut.CountLabels("cap", "overflow", "requests", []string{"route"}, []string{"/a"})
+	sut.CountLabels("cap", "overflow", "requests", []string{"route"}, []string{"/b"})
+
+	assert.Equal(t, "cap/overflow", overflowedKey)
+	assert.Equal(t, []string{"/b"}, overflowedValues)
+}
+
+func TestMetrics_HistogramVecCardinalityCap(t *testing.T) {
+	log, _ := logger.New(make(map[string]string))
+	sut := NewMetricsWithRegistry("ns", log, prometheus.NewRegistry())
+
+	vecA := sut.AddHistogramVec("cap", "latency", "latency", []string{"route"}, []string{"/a"}, WithMaxCardinality(1))
+	vecA.Observe(1)
+	vecB := sut.AddHistogramVec("cap", "latency", "latency", []string{"route"}, []string{"/b"}, WithMaxCardinality(1))
+	vecB.Observe(2)
+
+	assert.Equal(t, 1, sut.Cardinality("cap", "latency"))
+}
+
+func TestMetrics_NativeHistogramVecCardinalityCap(t *testing.T) {
+	log, _ := logger.New(make(map[string]string))
+	sut := NewMetricsWithRegistry("ns", log, prometheus.NewRegistry())
+
+	vecA := sut.AddNativeHistogramVec("cap", "native_latency", "latency", []string{"route"}, []string{"/a"},
+		NativeHistogramOptions{}, WithMaxCardinality(1))
+	vecA.Observe(1)
+	vecB := sut.AddNativeHistogramVec("cap", "native_latency", "latency", []string{"route"}, []string{"/b"},
+		NativeHistogramOptions{}, WithMaxCardinality(1))
+	vecB.Observe(2)
+
+	assert.Equal(t, 1, sut.Cardinality("cap", "native_latency"))
+}
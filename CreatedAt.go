@@ -0,0 +1,230 @@
+package metrics
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// client_golang does not yet expose a public "now" override on CounterOpts/
+// HistogramOpts/SummaryOpts to emit an OpenMetrics _created series directly,
+// so this wrapper tracks creation time itself and exposes it via CreatedAt.
+// That makes CreatedAt a programmatic accessor only: nothing here is wired
+// into promhttp/Handler's scrape output, so the timestamp is not visible as
+// a "_created" series to a scraper, only to code calling CreatedAt directly.
+// Once client_golang exposes that override, it can be wired straight into
+// the Opts structs to also make it scrape-visible.
+
+// metricMeta records what a metric was registered with, so Reset can
+// unregister and re-create it identically.
+type metricMeta struct {
+	kind       expiryKind
+	subsystem  string
+	name       string
+	help       string
+	labels     []string
+	buckets    []float64
+	objectives map[float64]float64
+	nativeOpts NativeHistogramOptions
+}
+
+// recordMeta stores meta and stamps the creation timestamp for key. It must
+// be called once, at registration time, while the caller already holds the
+// relevant map's write lock.
+func (m *Metrics) recordMeta(key string, meta *metricMeta) {
+	m.createdMutex.Lock()
+	m.createdAt[key] = time.Now()
+	m.meta[key] = meta
+	m.createdMutex.Unlock()
+}
+
+// CreatedAt returns the time the metric identified by subsystem/name was
+// last registered (or re-created by Reset), and whether it has been
+// registered at all. It reflects
+// the prometheus/client_golang "created timestamp" (CT) extension, letting
+// consumers compute correct rate() behavior across process restarts and
+// explicit Reset calls.
+func (m *Metrics) CreatedAt(subsystem, name string) (time.Time, bool) {
+	key := fmt.Sprintf("%s/%s", subsystem, name)
+	m.createdMutex.RLock()
+	defer m.createdMutex.RUnlock()
+	t, ok := m.createdAt[key]
+	return t, ok
+}
+
+// Reset unregisters the collector for the specified subsystem/name and
+// re-registers it from scratch, clearing its observed values and updating
+// the stored created timestamp. It is a no-op if no metric has been
+// registered under that key. Handles previously returned by AddHistogram,
+// AddHistogramVec, AddSummaryVec, AddNativeHistogram and AddNativeHistogramVec
+// re-resolve their underlying collector by key on every call, so callers do
+// not need to re-fetch them after calling Reset.
+func (m *Metrics) Reset(subsystem, name string) {
+	key := fmt.Sprintf("%s/%s", subsystem, name)
+
+	m.createdMutex.RLock()
+	meta, ok := m.meta[key]
+	m.createdMutex.RUnlock()
+	if !ok {
+		return
+	}
+
+	switch meta.kind {
+	case expiryCounter:
+		m.countMutex.Lock()
+		if c, ok := m.Counters[key]; ok {
+			m.unregister(c)
+		}
+		counter := prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: meta.subsystem,
+			Name:      meta.name,
+			Help:      meta.help,
+		})
+		m.Counters[key] = counter
+		m.mustRegister(counter)
+		m.countMutex.Unlock()
+	case expiryGauge:
+		m.gaugeMutex.Lock()
+		if g, ok := m.Gauges[key]; ok {
+			m.unregister(g)
+		}
+		gauge := prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: meta.subsystem,
+			Name:      meta.name,
+			Help:      meta.help,
+		})
+		m.Gauges[key] = gauge
+		m.mustRegister(gauge)
+		m.gaugeMutex.Unlock()
+	case expiryCounterVec:
+		m.countVecMutex.Lock()
+		if c, ok := m.CounterVecs[key]; ok {
+			m.unregister(c)
+		}
+		vec := prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: meta.subsystem,
+			Name:      meta.name,
+			Help:      meta.help,
+		}, meta.labels)
+		m.CounterVecs[key] = vec
+		m.mustRegister(vec)
+		m.countVecMutex.Unlock()
+	case expiryHistogram:
+		m.histMutex.Lock()
+		if h, ok := m.Histograms[key]; ok {
+			m.unregister(h)
+		}
+		if s, ok := m.Summaries[key]; ok {
+			m.unregister(s)
+		}
+		sum := prometheus.NewSummary(prometheus.SummaryOpts{
+			Namespace:  m.Namespace,
+			Subsystem:  meta.subsystem,
+			Name:       meta.name + "_summary",
+			Help:       meta.help,
+			Objectives: DefaultObjectives(),
+		})
+		m.Summaries[key] = sum
+		m.mustRegister(sum)
+
+		hist := prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: m.Namespace,
+			Subsystem: meta.subsystem,
+			Name:      meta.name,
+			Help:      meta.help,
+			Buckets:   meta.buckets,
+		})
+		m.Histograms[key] = hist
+		m.mustRegister(hist)
+		m.histMutex.Unlock()
+	case expiryHistogramOnly:
+		m.histMutex.Lock()
+		if h, ok := m.Histograms[key]; ok {
+			m.unregister(h)
+		}
+		hist := prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: m.Namespace,
+			Subsystem: meta.subsystem,
+			Name:      meta.name,
+			Help:      meta.help,
+			Buckets:   meta.buckets,
+		})
+		m.Histograms[key] = hist
+		m.mustRegister(hist)
+		m.histMutex.Unlock()
+	case expiryHistogramVec:
+		m.histVecMutex.Lock()
+		if v, ok := m.HistogramVecs[key]; ok {
+			m.unregister(v)
+		}
+		vec := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: m.Namespace,
+			Subsystem: meta.subsystem,
+			Name:      meta.name,
+			Help:      meta.help,
+			Buckets:   meta.buckets,
+		}, meta.labels)
+		m.HistogramVecs[key] = vec
+		m.mustRegister(vec)
+		m.histVecMutex.Unlock()
+	case expirySummaryVec:
+		m.summaryVecMutex.Lock()
+		if v, ok := m.SummaryVecs[key]; ok {
+			m.unregister(v)
+		}
+		vec := prometheus.NewSummaryVec(prometheus.SummaryOpts{
+			Namespace:  m.Namespace,
+			Subsystem:  meta.subsystem,
+			Name:       meta.name + "_summary",
+			Help:       meta.help,
+			Objectives: meta.objectives,
+		}, meta.labels)
+		m.SummaryVecs[key] = vec
+		m.mustRegister(vec)
+		m.summaryVecMutex.Unlock()
+	case expiryNativeHistogram:
+		m.nativeHistMutex.Lock()
+		if h, ok := m.NativeHistograms[key]; ok {
+			m.unregister(h)
+		}
+		hist := prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:                       m.Namespace,
+			Subsystem:                       meta.subsystem,
+			Name:                            meta.name,
+			Help:                            meta.help,
+			NativeHistogramBucketFactor:     meta.nativeOpts.BucketFactor,
+			NativeHistogramMaxBucketNumber:  meta.nativeOpts.MaxBucketNumber,
+			NativeHistogramMinResetDuration: meta.nativeOpts.MinResetDuration,
+			NativeHistogramZeroThreshold:    meta.nativeOpts.ZeroThreshold,
+		})
+		m.NativeHistograms[key] = hist
+		m.mustRegister(hist)
+		m.nativeHistMutex.Unlock()
+	case expiryNativeHistogramVec:
+		m.nativeHistVecMutex.Lock()
+		if v, ok := m.NativeHistogramVecs[key]; ok {
+			m.unregister(v)
+		}
+		vec := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:                       m.Namespace,
+			Subsystem:                       meta.subsystem,
+			Name:                            meta.name,
+			Help:                            meta.help,
+			NativeHistogramBucketFactor:     meta.nativeOpts.BucketFactor,
+			NativeHistogramMaxBucketNumber:  meta.nativeOpts.MaxBucketNumber,
+			NativeHistogramMinResetDuration: meta.nativeOpts.MinResetDuration,
+			NativeHistogramZeroThreshold:    meta.nativeOpts.ZeroThreshold,
+		}, meta.labels)
+		m.NativeHistogramVecs[key] = vec
+		m.mustRegister(vec)
+		m.nativeHistVecMutex.Unlock()
+	}
+
+	m.createdMutex.Lock()
+	m.createdAt[key] = time.Now()
+	m.createdMutex.Unlock()
+}
@@ -0,0 +1,65 @@
+package metrics // white-box test
+
+import (
+	"testing"
+
+	"github.com/Travix-International/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics_CreatedAt(t *testing.T) {
+	log, _ := logger.New(make(map[string]string))
+	sut := NewMetricsWithRegistry("ns", log, prometheus.NewRegistry())
+
+	_, ok := sut.CreatedAt("created", "counter")
+	assert.False(t, ok)
+
+	sut.Count("created", "counter", "created counter")
+	createdAt, ok := sut.CreatedAt("created", "counter")
+	assert.True(t, ok)
+	assert.False(t, createdAt.IsZero())
+}
+
+func TestMetrics_Reset(t *testing.T) {
+	log, _ := logger.New(make(map[string]string))
+	sut := NewMetricsWithRegistry("ns", log, prometheus.NewRegistry())
+
+	sut.Count("reset", "counter", "reset counter")
+	sut.Count("reset", "counter", "reset counter")
+	before, _ := sut.CreatedAt("reset", "counter")
+
+	sut.Reset("reset", "counter")
+
+	after, ok := sut.CreatedAt("reset", "counter")
+	assert.True(t, ok)
+	assert.True(t, after.After(before) || after.Equal(before))
+	assert.Equal(t, 1, len(sut.Counters))
+}
+
+func TestMetrics_ResetUpdatesPreviouslyObtainedHandle(t *testing.T) {
+	log, _ := logger.New(make(map[string]string))
+	reg := prometheus.NewRegistry()
+	sut := NewMetricsWithRegistry("ns", log, reg)
+
+	hist := sut.AddHistogram("reset", "hist", "reset histogram")
+	hist.Observe(1)
+
+	sut.Reset("reset", "hist")
+
+	// hist was obtained before Reset recreated the collector; it must keep
+	// observing into the collector that's actually registered rather than
+	// silently dropping observations into the unregistered original.
+	hist.Observe(2)
+
+	families, err := reg.Gather()
+	assert.NoError(t, err)
+
+	var sampleCount uint64
+	for _, f := range families {
+		if f.GetName() == "ns_reset_hist" {
+			sampleCount = f.GetMetric()[0].GetHistogram().GetSampleCount()
+		}
+	}
+	assert.Equal(t, uint64(1), sampleCount)
+}
@@ -0,0 +1,197 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Travix-International/logger"
+)
+
+// defaultExpiryScanInterval is used by StartExpiry when Metrics.ExpiryScanInterval is left zero.
+const defaultExpiryScanInterval = time.Minute
+
+// expiryKind identifies which collector map an expiryEntry belongs to, so the
+// evictor knows how to unregister it.
+type expiryKind int
+
+const (
+	expiryCounter expiryKind = iota
+	expiryGauge
+	expiryHistogram
+	// expiryHistogramOnly is like expiryHistogram but for metrics registered
+	// via AddHistogramOnly, which have no paired Summary to unregister.
+	expiryHistogramOnly
+	expiryCounterVec
+	expiryHistogramVec
+	expirySummaryVec
+	expiryNativeHistogram
+	expiryNativeHistogramVec
+)
+
+// expiryEntry tracks the last time a metric (or, for *Vec types, a single
+// label-value tuple of a metric) was touched, and the TTL that applies to it.
+type expiryEntry struct {
+	kind      expiryKind
+	key       string
+	labelVals []string
+	lastTouch time.Time
+	ttl       time.Duration
+}
+
+const labelTupleSep = "\x1f"
+
+// expiryIndexKey builds the sync.Map key for a metric/label-tuple pair.
+func expiryIndexKey(key string, labelVals []string) string {
+	if len(labelVals) == 0 {
+		return key
+	}
+	return key + labelTupleSep + strings.Join(labelVals, labelTupleSep)
+}
+
+// NewMetricsWithTTL instantiates a new Metrics wrapper with TTL-based expiry
+// enabled: metrics (and, for *Vec types, individual label-value tuples) that
+// go untouched for longer than ttl are evicted by StartExpiry's background
+// scan. Per-call TTLs can be set with WithTTL.
+func NewMetricsWithTTL(namespace string, logger *logger.Logger, ttl time.Duration) *Metrics {
+	m := NewMetrics(namespace, logger)
+	m.TTL = ttl
+	m.expiryIndex = &sync.Map{}
+	return m
+}
+
+// touch records (or refreshes) the last-observation timestamp for key (and,
+// for *Vec types, labelVals). It is a no-op until the expiry index exists
+// (created by NewMetricsWithTTL or StartExpiry), and when neither ttl nor
+// Metrics.TTL is positive.
+func (m *Metrics) touch(kind expiryKind, key string, labelVals []string, ttl time.Duration) {
+	if m.expiryIndex == nil {
+		return
+	}
+	if ttl <= 0 {
+		ttl = m.TTL
+	}
+	if ttl <= 0 {
+		return
+	}
+	m.expiryIndex.Store(expiryIndexKey(key, labelVals), &expiryEntry{
+		kind:      kind,
+		key:       key,
+		labelVals: labelVals,
+		lastTouch: time.Now(),
+		ttl:       ttl,
+	})
+}
+
+// StartExpiry starts the background goroutine that scans the expiry index
+// every Metrics.ExpiryScanInterval (defaulting to defaultExpiryScanInterval)
+// and evicts metrics that have not been touched within their TTL. The
+// goroutine stops when ctx is done.
+func (m *Metrics) StartExpiry(ctx context.Context) {
+	if m.expiryIndex == nil {
+		m.expiryIndex = &sync.Map{}
+	}
+	interval := m.ExpiryScanInterval
+	if interval <= 0 {
+		interval = defaultExpiryScanInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.evictExpired()
+			}
+		}
+	}()
+}
+
+func (m *Metrics) evictExpired() {
+	now := time.Now()
+	m.expiryIndex.Range(func(k, v interface{}) bool {
+		entry := v.(*expiryEntry)
+		if now.Sub(entry.lastTouch) <= entry.ttl {
+			return true
+		}
+		m.evict(entry)
+		m.expiryIndex.Delete(k)
+		return true
+	})
+}
+
+func (m *Metrics) evict(entry *expiryEntry) {
+	switch entry.kind {
+	case expiryCounter:
+		m.countMutex.Lock()
+		if c, ok := m.Counters[entry.key]; ok {
+			m.unregister(c)
+			delete(m.Counters, entry.key)
+		}
+		m.countMutex.Unlock()
+	case expiryGauge:
+		m.gaugeMutex.Lock()
+		if g, ok := m.Gauges[entry.key]; ok {
+			m.unregister(g)
+			delete(m.Gauges, entry.key)
+		}
+		m.gaugeMutex.Unlock()
+	case expiryHistogram:
+		m.histMutex.Lock()
+		if h, ok := m.Histograms[entry.key]; ok {
+			m.unregister(h)
+			delete(m.Histograms, entry.key)
+		}
+		if s, ok := m.Summaries[entry.key]; ok {
+			m.unregister(s)
+			delete(m.Summaries, entry.key)
+		}
+		m.histMutex.Unlock()
+	case expiryHistogramOnly:
+		m.histMutex.Lock()
+		if h, ok := m.Histograms[entry.key]; ok {
+			m.unregister(h)
+			delete(m.Histograms, entry.key)
+		}
+		m.histMutex.Unlock()
+	case expiryCounterVec:
+		m.countVecMutex.RLock()
+		vec, ok := m.CounterVecs[entry.key]
+		m.countVecMutex.RUnlock()
+		if ok {
+			vec.DeleteLabelValues(entry.labelVals...)
+		}
+	case expiryHistogramVec:
+		m.histVecMutex.RLock()
+		vec, ok := m.HistogramVecs[entry.key]
+		m.histVecMutex.RUnlock()
+		if ok {
+			vec.DeleteLabelValues(entry.labelVals...)
+		}
+	case expirySummaryVec:
+		m.summaryVecMutex.RLock()
+		vec, ok := m.SummaryVecs[entry.key]
+		m.summaryVecMutex.RUnlock()
+		if ok {
+			vec.DeleteLabelValues(entry.labelVals...)
+		}
+	case expiryNativeHistogram:
+		m.nativeHistMutex.Lock()
+		if h, ok := m.NativeHistograms[entry.key]; ok {
+			m.unregister(h)
+			delete(m.NativeHistograms, entry.key)
+		}
+		m.nativeHistMutex.Unlock()
+	case expiryNativeHistogramVec:
+		m.nativeHistVecMutex.RLock()
+		vec, ok := m.NativeHistogramVecs[entry.key]
+		m.nativeHistVecMutex.RUnlock()
+		if ok {
+			vec.DeleteLabelValues(entry.labelVals...)
+		}
+	}
+}
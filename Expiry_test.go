@@ -0,0 +1,39 @@
+package metrics // white-box test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Travix-International/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics_TTLEvictsUntouchedCounter(t *testing.T) {
+	log, _ := logger.New(make(map[string]string))
+	sut := NewMetricsWithTTL("ns", log, time.Millisecond)
+	sut.Registerer = prometheus.NewRegistry()
+
+	sut.Count("ttl", "counter", "ttl counter")
+	assert.Equal(t, 1, len(sut.Counters))
+
+	time.Sleep(5 * time.Millisecond)
+	sut.evictExpired()
+
+	assert.Equal(t, 0, len(sut.Counters))
+}
+
+func TestMetrics_TTLPerCallOverride(t *testing.T) {
+	log, _ := logger.New(make(map[string]string))
+	sut := NewMetricsWithTTL("ns", log, 0)
+	sut.Registerer = prometheus.NewRegistry()
+
+	sut.CountLabels("ttl", "withopt", "ttl counter vec", []string{"lbl"}, []string{"val"}, WithTTL(time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sut.ExpiryScanInterval = time.Millisecond
+	sut.StartExpiry(ctx)
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+}
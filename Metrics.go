@@ -12,65 +12,150 @@ import (
 type (
 	// Metrics provides a set of convenience functions that wrap Prometheus
 	Metrics struct {
-		Namespace       string
-		Counters        map[string]prometheus.Counter
-		CounterVecs     map[string]*prometheus.CounterVec
-		Summaries       map[string]prometheus.Summary
-		SummaryVecs     map[string]*prometheus.SummaryVec
-		Histograms      map[string]prometheus.Histogram
-		HistogramVecs   map[string]*prometheus.HistogramVec
-		Gauges          map[string]prometheus.Gauge
-		Logger          *logger.Logger
-		countMutex      *sync.RWMutex
-		countVecMutex   *sync.RWMutex
-		histMutex       *sync.RWMutex
-		histVecMutex    *sync.RWMutex
-		summaryVecMutex *sync.RWMutex
-		gaugeMutex      *sync.RWMutex
+		Namespace     string
+		Counters      map[string]prometheus.Counter
+		CounterVecs   map[string]*prometheus.CounterVec
+		Summaries     map[string]prometheus.Summary
+		SummaryVecs   map[string]*prometheus.SummaryVec
+		Histograms    map[string]prometheus.Histogram
+		HistogramVecs map[string]*prometheus.HistogramVec
+		Gauges        map[string]prometheus.Gauge
+		// NativeHistograms and NativeHistogramVecs hold the sparse,
+		// exponential-bucket histograms registered via AddNativeHistogram(Vec).
+		// They are kept separate from Histograms/HistogramVecs since a native
+		// histogram never has a paired Summary.
+		NativeHistograms    map[string]prometheus.Histogram
+		NativeHistogramVecs map[string]*prometheus.HistogramVec
+		Logger              *logger.Logger
+		// Registerer is where every metric is registered and unregistered.
+		// It defaults to prometheus.DefaultRegisterer so existing callers are
+		// unaffected; set it (via NewMetricsWithRegistry) to isolate a
+		// Metrics instance, e.g. for parallel tests or multi-tenant servers.
+		Registerer prometheus.Registerer
+		// TTL is the default time a metric (or, for *Vec types, a single
+		// label-value tuple) may go untouched before it is evicted by the
+		// expiry scan. Zero means no default TTL; metrics registered with
+		// WithTTL still expire after their own TTL.
+		TTL time.Duration
+		// ExpiryScanInterval controls how often StartExpiry scans for expired
+		// metrics. Defaults to defaultExpiryScanInterval when left zero.
+		ExpiryScanInterval time.Duration
+		expiryIndex        *sync.Map
+		createdAt          map[string]time.Time
+		meta               map[string]*metricMeta
+		createdMutex       *sync.RWMutex
+		countMutex         *sync.RWMutex
+		countVecMutex      *sync.RWMutex
+		histMutex          *sync.RWMutex
+		histVecMutex       *sync.RWMutex
+		summaryVecMutex    *sync.RWMutex
+		gaugeMutex         *sync.RWMutex
+		nativeHistMutex    *sync.RWMutex
+		nativeHistVecMutex *sync.RWMutex
+		// MaxCardinality is the process-wide default cap on the number of
+		// distinct label-value tuples a CountLabels/HistogramVec/SummaryVec
+		// metric will track, applied whenever a call doesn't override it via
+		// WithMaxCardinality. Zero leaves cardinality unbounded.
+		MaxCardinality int
+		// OverflowValue is substituted for a tuple's label values once its
+		// metric's cardinality cap is reached. Defaults to DefaultOverflowValue.
+		OverflowValue              string
+		cardinalityIndex           *sync.Map
+		cardinalityOverflowCounter *prometheus.CounterVec
+		cardinalityOverflowMutex   *sync.Mutex
+		cardinalityCallback        func(key string, labels, values []string)
+		cardinalityCallbackMutex   *sync.RWMutex
 	}
 
 	// MetricsHistogram combines a histogram and summary
 	MetricsHistogram struct {
-		Key  string
-		hist prometheus.Histogram
-		sum  prometheus.Summary
+		Key     string
+		hist    prometheus.Histogram
+		sum     prometheus.Summary
+		metrics *Metrics
+		ttl     time.Duration
 	}
 
 	// HistogramVec wraps prometheus.HistogramVec
 	HistogramVec struct {
-		Key         string
-		Labels      []string
-		LabelValues []string
-		histVec     *prometheus.HistogramVec
+		Key            string
+		Labels         []string
+		LabelValues    []string
+		histVec        *prometheus.HistogramVec
+		metrics        *Metrics
+		ttl            time.Duration
+		maxCardinality int
+		overflowValue  string
 	}
 
 	// SummaryVec wraps prometheus.SummaryVec
 	SummaryVec struct {
-		Key         string
-		Labels      []string
-		LabelValues []string
-		summaryVec  *prometheus.SummaryVec
+		Key            string
+		Labels         []string
+		LabelValues    []string
+		summaryVec     *prometheus.SummaryVec
+		metrics        *Metrics
+		ttl            time.Duration
+		maxCardinality int
+		overflowValue  string
+	}
+
+	// Option customizes a single registration call, e.g. WithTTL.
+	Option func(*registrationOptions)
+
+	registrationOptions struct {
+		ttl            time.Duration
+		maxCardinality int
+		overflowValue  string
 	}
 )
 
+// WithTTL overrides the Metrics-wide TTL for the metric (or label-value
+// tuple) being registered by this call.
+func WithTTL(ttl time.Duration) Option {
+	return func(o *registrationOptions) {
+		o.ttl = ttl
+	}
+}
+
+func applyOptions(opts []Option) registrationOptions {
+	var o registrationOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
 // NewMetrics will instantiate a new Metrics wrapper object
 func NewMetrics(namespace string, logger *logger.Logger) *Metrics {
 	m := Metrics{
-		Namespace:       namespace,
-		Logger:          logger,
-		Counters:        make(map[string]prometheus.Counter),
-		CounterVecs:     make(map[string]*prometheus.CounterVec),
-		Histograms:      make(map[string]prometheus.Histogram),
-		HistogramVecs:   make(map[string]*prometheus.HistogramVec),
-		Summaries:       make(map[string]prometheus.Summary),
-		SummaryVecs:     make(map[string]*prometheus.SummaryVec),
-		Gauges:          make(map[string]prometheus.Gauge),
-		countMutex:      &sync.RWMutex{},
-		countVecMutex:   &sync.RWMutex{},
-		histMutex:       &sync.RWMutex{},
-		histVecMutex:    &sync.RWMutex{},
-		summaryVecMutex: &sync.RWMutex{},
-		gaugeMutex:      &sync.RWMutex{},
+		Namespace:                namespace,
+		Logger:                   logger,
+		Counters:                 make(map[string]prometheus.Counter),
+		CounterVecs:              make(map[string]*prometheus.CounterVec),
+		Histograms:               make(map[string]prometheus.Histogram),
+		HistogramVecs:            make(map[string]*prometheus.HistogramVec),
+		Summaries:                make(map[string]prometheus.Summary),
+		SummaryVecs:              make(map[string]*prometheus.SummaryVec),
+		Gauges:                   make(map[string]prometheus.Gauge),
+		NativeHistograms:         make(map[string]prometheus.Histogram),
+		NativeHistogramVecs:      make(map[string]*prometheus.HistogramVec),
+		Registerer:               prometheus.DefaultRegisterer,
+		createdAt:                make(map[string]time.Time),
+		meta:                     make(map[string]*metricMeta),
+		createdMutex:             &sync.RWMutex{},
+		countMutex:               &sync.RWMutex{},
+		countVecMutex:            &sync.RWMutex{},
+		histMutex:                &sync.RWMutex{},
+		histVecMutex:             &sync.RWMutex{},
+		summaryVecMutex:          &sync.RWMutex{},
+		gaugeMutex:               &sync.RWMutex{},
+		nativeHistMutex:          &sync.RWMutex{},
+		nativeHistVecMutex:       &sync.RWMutex{},
+		OverflowValue:            DefaultOverflowValue,
+		cardinalityIndex:         &sync.Map{},
+		cardinalityOverflowMutex: &sync.Mutex{},
+		cardinalityCallbackMutex: &sync.RWMutex{},
 	}
 	return &m
 }
@@ -81,7 +166,7 @@ func DefaultObjectives() map[float64]float64 {
 }
 
 // Count increases the counter for the specified subsystem and name.
-func (m *Metrics) Count(subsystem, name, help string) {
+func (m *Metrics) Count(subsystem, name, help string, opts ...Option) {
 	m.countMutex.RLock()
 	key := fmt.Sprintf("%s/%s", subsystem, name)
 	counter, exists := m.Counters[key]
@@ -97,7 +182,8 @@ func (m *Metrics) Count(subsystem, name, help string) {
 				Help:      help,
 			})
 			m.Counters[key] = counter
-			err := prometheus.Register(counter)
+			m.recordMeta(key, &metricMeta{kind: expiryCounter, subsystem: subsystem, name: name, help: help})
+			err := m.register(counter)
 			if err != nil {
 				m.Logger.Warn("MetricsCounterRegistrationFailed",
 					fmt.Sprintf("CounterHandler: Counter registration %v failed: %v", counter, err))
@@ -107,10 +193,11 @@ func (m *Metrics) Count(subsystem, name, help string) {
 	}
 
 	counter.Inc()
+	m.touch(expiryCounter, key, nil, applyOptions(opts).ttl)
 }
 
 // SetGauge sets the gauge value for the specified subsystem and name.
-func (m *Metrics) SetGauge(value float64, subsystem, name, help string) {
+func (m *Metrics) SetGauge(value float64, subsystem, name, help string, opts ...Option) {
 	m.gaugeMutex.RLock()
 	key := fmt.Sprintf("%s/%s", subsystem, name)
 	gauge, exists := m.Gauges[key]
@@ -126,7 +213,8 @@ func (m *Metrics) SetGauge(value float64, subsystem, name, help string) {
 				Help:      help,
 			})
 			m.Gauges[key] = gauge
-			err := prometheus.Register(gauge)
+			m.recordMeta(key, &metricMeta{kind: expiryGauge, subsystem: subsystem, name: name, help: help})
+			err := m.register(gauge)
 			if err != nil {
 				m.Logger.Warn("MetricsSetGaugeFailed",
 					fmt.Sprintf("SetGauge: Gauge registration %v failed: %v", gauge, err))
@@ -136,10 +224,11 @@ func (m *Metrics) SetGauge(value float64, subsystem, name, help string) {
 	}
 
 	gauge.Set(value)
+	m.touch(expiryGauge, key, nil, applyOptions(opts).ttl)
 }
 
 // CountLabels increases the counter for the specified subsystem and name and adds the specified labels with values.
-func (m *Metrics) CountLabels(subsystem, name, help string, labels, values []string) {
+func (m *Metrics) CountLabels(subsystem, name, help string, labels, values []string, opts ...Option) {
 	m.countVecMutex.RLock()
 	key := fmt.Sprintf("%s/%s", subsystem, name)
 	counter, exists := m.CounterVecs[key]
@@ -155,7 +244,8 @@ func (m *Metrics) CountLabels(subsystem, name, help string, labels, values []str
 				Help:      help,
 			}, labels)
 			m.CounterVecs[key] = counter
-			err := prometheus.Register(counter)
+			m.recordMeta(key, &metricMeta{kind: expiryCounterVec, subsystem: subsystem, name: name, help: help, labels: labels})
+			err := m.register(counter)
 			if err != nil {
 				m.Logger.Warn("MetricsCounterLabelRegistrationFailed",
 					fmt.Sprintf("CounterLabelHandler: Counter registration %v failed: %v", counter, err))
@@ -164,11 +254,23 @@ func (m *Metrics) CountLabels(subsystem, name, help string, labels, values []str
 		m.countVecMutex.Unlock()
 	}
 
-	counter.WithLabelValues(values...).Inc()
+	ro := applyOptions(opts)
+	max := ro.maxCardinality
+	if max == 0 {
+		max = m.MaxCardinality
+	}
+	overflow := ro.overflowValue
+	if overflow == "" {
+		overflow = m.OverflowValue
+	}
+	guarded := m.guardCardinality(key, labels, values, max, overflow)
+
+	counter.WithLabelValues(guarded...).Inc()
+	m.touch(expiryCounterVec, key, guarded, ro.ttl)
 }
 
 // IncreaseCounter increases the counter for the specified subsystem and name with the specified increment.
-func (m *Metrics) IncreaseCounter(subsystem, name, help string, increment int) {
+func (m *Metrics) IncreaseCounter(subsystem, name, help string, increment int, opts ...Option) {
 	m.countMutex.RLock()
 	key := fmt.Sprintf("%s/%s", subsystem, name)
 	counter, exists := m.Counters[key]
@@ -184,7 +286,8 @@ func (m *Metrics) IncreaseCounter(subsystem, name, help string, increment int) {
 				Help:      help,
 			})
 			m.Counters[key] = counter
-			err := prometheus.Register(counter)
+			m.recordMeta(key, &metricMeta{kind: expiryCounter, subsystem: subsystem, name: name, help: help})
+			err := m.register(counter)
 			if err != nil {
 				m.Logger.Warn("MetricsIncreaseCounterRegistrationFailed",
 					fmt.Sprintf("CounterHandler: Counter registration failed: %v: %v", counter, err))
@@ -194,19 +297,57 @@ func (m *Metrics) IncreaseCounter(subsystem, name, help string, increment int) {
 	}
 
 	counter.Add(float64(increment))
+	m.touch(expiryCounter, key, nil, applyOptions(opts).ttl)
 }
 
 // AddHistogram returns the MetricsHistogram for the specified subsystem and name.
-func (m *Metrics) AddHistogram(subsystem, name, help string) *MetricsHistogram {
-	return m.addHistogramWithBuckets(subsystem, name, help, prometheus.DefBuckets)
+func (m *Metrics) AddHistogram(subsystem, name, help string, opts ...Option) *MetricsHistogram {
+	return m.addHistogramWithBuckets(subsystem, name, help, prometheus.DefBuckets, opts)
 }
 
 // AddHistogramWithCustomBuckets returns the MetricsHistogram for the specified subsystem and name with the specified buckets.
-func (m *Metrics) AddHistogramWithCustomBuckets(subsystem, name, help string, buckets []float64) *MetricsHistogram {
-	return m.addHistogramWithBuckets(subsystem, name, help, buckets)
+func (m *Metrics) AddHistogramWithCustomBuckets(subsystem, name, help string, buckets []float64, opts ...Option) *MetricsHistogram {
+	return m.addHistogramWithBuckets(subsystem, name, help, buckets, opts)
 }
 
-func (m *Metrics) addHistogramWithBuckets(subsystem, name, help string, buckets []float64) *MetricsHistogram {
+// AddHistogramOnly returns the MetricsHistogram for the specified subsystem and name, without the paired Summary
+// that AddHistogram/AddHistogramWithCustomBuckets also register. Prefer this for services with many histograms,
+// where the extra Summary's memory overhead adds up; AddHistogram is kept for existing consumers of that pair.
+func (m *Metrics) AddHistogramOnly(subsystem, name, help string, buckets []float64, opts ...Option) *MetricsHistogram {
+	m.histMutex.RLock()
+	key := fmt.Sprintf("%s/%s", subsystem, name)
+	hist, exists := m.Histograms[key]
+	m.histMutex.RUnlock()
+
+	if !exists {
+		m.histMutex.Lock()
+		if hist, exists = m.Histograms[key]; !exists {
+			hist = prometheus.NewHistogram(prometheus.HistogramOpts{
+				Namespace: m.Namespace,
+				Subsystem: subsystem,
+				Name:      name,
+				Help:      help,
+				Buckets:   buckets,
+			})
+			m.mustRegister(hist)
+			m.Histograms[key] = hist
+			m.recordMeta(key, &metricMeta{kind: expiryHistogramOnly, subsystem: subsystem, name: name, help: help, buckets: buckets})
+		}
+		m.histMutex.Unlock()
+	}
+
+	ttl := applyOptions(opts).ttl
+	mh := MetricsHistogram{
+		Key:     key,
+		hist:    hist,
+		metrics: m,
+		ttl:     ttl,
+	}
+	m.touch(expiryHistogramOnly, key, nil, ttl)
+	return &mh
+}
+
+func (m *Metrics) addHistogramWithBuckets(subsystem, name, help string, buckets []float64, opts []Option) *MetricsHistogram {
 	m.histMutex.RLock()
 	key := fmt.Sprintf("%s/%s", subsystem, name)
 	sum, exists := m.Summaries[key]
@@ -224,7 +365,7 @@ func (m *Metrics) addHistogramWithBuckets(subsystem, name, help string, buckets
 				Help:       help,
 				Objectives: DefaultObjectives(),
 			})
-			prometheus.MustRegister(sum)
+			m.mustRegister(sum)
 			m.Summaries[key] = sum
 
 			hist = prometheus.NewHistogram(prometheus.HistogramOpts{
@@ -234,34 +375,39 @@ func (m *Metrics) addHistogramWithBuckets(subsystem, name, help string, buckets
 				Help:      help,
 				Buckets:   buckets,
 			})
-			prometheus.MustRegister(hist)
+			m.mustRegister(hist)
 			m.Histograms[key] = hist
+			m.recordMeta(key, &metricMeta{kind: expiryHistogram, subsystem: subsystem, name: name, help: help, buckets: buckets})
 		}
 		m.histMutex.Unlock()
 	}
 
+	ttl := applyOptions(opts).ttl
 	mh := MetricsHistogram{
-		Key:  key,
-		hist: hist,
-		sum:  sum,
+		Key:     key,
+		hist:    hist,
+		sum:     sum,
+		metrics: m,
+		ttl:     ttl,
 	}
+	m.touch(expiryHistogram, key, nil, ttl)
 	return &mh
 }
 
 // AddHistogramVec returns the HistogramVec for the specified subsystem and name.
-func (m *Metrics) AddHistogramVec(subsystem, name, help string, labels, labelValues []string) *HistogramVec {
-	return m.addHistogramVecWithBuckets(subsystem, name, help, labels, labelValues, prometheus.DefBuckets)
+func (m *Metrics) AddHistogramVec(subsystem, name, help string, labels, labelValues []string, opts ...Option) *HistogramVec {
+	return m.addHistogramVecWithBuckets(subsystem, name, help, labels, labelValues, prometheus.DefBuckets, opts)
 }
 
 // AddHistogramVecWithCustomBuckets returns the HistogramVec for the specified subsystem and name with the specified buckets.
 func (m *Metrics) AddHistogramVecWithCustomBuckets(subsystem, name, help string, labels, labelValues []string,
-	buckets []float64) *HistogramVec {
+	buckets []float64, opts ...Option) *HistogramVec {
 
-	return m.addHistogramVecWithBuckets(subsystem, name, help, labels, labelValues, buckets)
+	return m.addHistogramVecWithBuckets(subsystem, name, help, labels, labelValues, buckets, opts)
 }
 
 func (m *Metrics) addHistogramVecWithBuckets(subsystem, name, help string, labels, labelValues []string,
-	buckets []float64) *HistogramVec {
+	buckets []float64, opts []Option) *HistogramVec {
 
 	m.histVecMutex.RLock()
 	key := fmt.Sprintf("%s/%s", subsystem, name)
@@ -269,42 +415,59 @@ func (m *Metrics) addHistogramVecWithBuckets(subsystem, name, help string, label
 	m.histVecMutex.RUnlock()
 
 	if !exists {
-		m.histVecMutex.Lock()
-		vec = prometheus.NewHistogramVec(prometheus.HistogramOpts{
-			Namespace: m.Namespace,
-			Subsystem: subsystem,
-			Name:      name,
-			Help:      help,
-			Buckets:   buckets,
-		}, labels)
-		prometheus.MustRegister(vec)
-		m.HistogramVecs[key] = vec
-		m.histVecMutex.Unlock()
+		vec = m.registerHistogramVec(key, subsystem, name, help, labels, buckets)
 	}
 
+	ro := applyOptions(opts)
 	mh := HistogramVec{
-		Key:         key,
-		Labels:      labels,
-		LabelValues: labelValues,
-		histVec:     vec,
+		Key:            key,
+		Labels:         labels,
+		LabelValues:    labelValues,
+		histVec:        vec,
+		metrics:        m,
+		ttl:            ro.ttl,
+		maxCardinality: ro.maxCardinality,
+		overflowValue:  ro.overflowValue,
 	}
 	return &mh
 }
 
+// registerHistogramVec takes histVecMutex, re-checks for a concurrently-created entry, and registers a new
+// collector if none exists. The lock is released via defer so a registration panic can never leave it held.
+func (m *Metrics) registerHistogramVec(key, subsystem, name, help string, labels []string, buckets []float64) *prometheus.HistogramVec {
+	m.histVecMutex.Lock()
+	defer m.histVecMutex.Unlock()
+
+	if vec, exists := m.HistogramVecs[key]; exists {
+		return vec
+	}
+	vec := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: m.Namespace,
+		Subsystem: subsystem,
+		Name:      name,
+		Help:      help,
+		Buckets:   buckets,
+	}, labels)
+	vec = m.registerHistogramVecOrExisting(vec)
+	m.HistogramVecs[key] = vec
+	m.recordMeta(key, &metricMeta{kind: expiryHistogramVec, subsystem: subsystem, name: name, help: help, labels: labels, buckets: buckets})
+	return vec
+}
+
 // AddSummaryVec returns the SummaryVec for the specified subsystem and name.
-func (m *Metrics) AddSummaryVec(subsystem, name, help string, labels, labelValues []string) *SummaryVec {
-	return m.addSummaryVecWithObjectives(subsystem, name, help, labels, labelValues, DefaultObjectives())
+func (m *Metrics) AddSummaryVec(subsystem, name, help string, labels, labelValues []string, opts ...Option) *SummaryVec {
+	return m.addSummaryVecWithObjectives(subsystem, name, help, labels, labelValues, DefaultObjectives(), opts)
 }
 
 // AddSummaryVecWithCustomObjectives returns the SummaryVec for the specified subsystem and name with the specified objectives.
 func (m *Metrics) AddSummaryVecWithCustomObjectives(subsystem, name, help string, labels, labelValues []string,
-	objectives map[float64]float64) *SummaryVec {
+	objectives map[float64]float64, opts ...Option) *SummaryVec {
 
-	return m.addSummaryVecWithObjectives(subsystem, name, help, labels, labelValues, objectives)
+	return m.addSummaryVecWithObjectives(subsystem, name, help, labels, labelValues, objectives, opts)
 }
 
 func (m *Metrics) addSummaryVecWithObjectives(subsystem, name, help string, labels, labelValues []string,
-	objectives map[float64]float64) *SummaryVec {
+	objectives map[float64]float64, opts []Option) *SummaryVec {
 
 	m.summaryVecMutex.RLock()
 	key := fmt.Sprintf("%s/%s", subsystem, name)
@@ -312,34 +475,54 @@ func (m *Metrics) addSummaryVecWithObjectives(subsystem, name, help string, labe
 	m.summaryVecMutex.RUnlock()
 
 	if !exists {
-		m.summaryVecMutex.Lock()
-		vec = prometheus.NewSummaryVec(prometheus.SummaryOpts{
-			Namespace:  m.Namespace,
-			Subsystem:  subsystem,
-			Name:       name + "_summary",
-			Help:       help,
-			Objectives: objectives,
-		}, labels)
-		prometheus.MustRegister(vec)
-		m.SummaryVecs[key] = vec
-		m.summaryVecMutex.Unlock()
+		vec = m.registerSummaryVec(key, subsystem, name, help, labels, objectives)
 	}
 
+	ro := applyOptions(opts)
 	mh := SummaryVec{
-		Key:         key,
-		Labels:      labels,
-		LabelValues: labelValues,
-		summaryVec:  vec,
+		Key:            key,
+		Labels:         labels,
+		LabelValues:    labelValues,
+		summaryVec:     vec,
+		metrics:        m,
+		ttl:            ro.ttl,
+		maxCardinality: ro.maxCardinality,
+		overflowValue:  ro.overflowValue,
 	}
 	return &mh
 }
 
+// registerSummaryVec takes summaryVecMutex, re-checks for a concurrently-created entry, and registers a new
+// collector if none exists. The lock is released via defer so a registration panic can never leave it held.
+func (m *Metrics) registerSummaryVec(key, subsystem, name, help string, labels []string, objectives map[float64]float64) *prometheus.SummaryVec {
+	m.summaryVecMutex.Lock()
+	defer m.summaryVecMutex.Unlock()
+
+	if vec, exists := m.SummaryVecs[key]; exists {
+		return vec
+	}
+	vec := prometheus.NewSummaryVec(prometheus.SummaryOpts{
+		Namespace:  m.Namespace,
+		Subsystem:  subsystem,
+		Name:       name + "_summary",
+		Help:       help,
+		Objectives: objectives,
+	}, labels)
+	vec = m.registerSummaryVecOrExisting(vec)
+	m.SummaryVecs[key] = vec
+	m.recordMeta(key, &metricMeta{kind: expirySummaryVec, subsystem: subsystem, name: name, help: help, labels: labels, objectives: objectives})
+	return vec
+}
+
 // RecordTimeElapsed adds the elapsed time since the specified start to the histogram in seconds and to the linked
 // summary in milliseconds.
 func (histogram *MetricsHistogram) RecordTimeElapsed(start time.Time) {
 	elapsed := float64(time.Since(start).Seconds())
-	histogram.hist.Observe(elapsed)         // The default histogram buckets are recorded in seconds
-	histogram.sum.Observe(elapsed * 1000.0) // While we have summaries in milliseconds
+	histogram.currentHist().Observe(elapsed) // The default histogram buckets are recorded in seconds
+	if sum := histogram.currentSum(); sum != nil {
+		sum.Observe(elapsed * 1000.0) // While we have summaries in milliseconds
+	}
+	histogram.touch()
 }
 
 // RecordDuration adds the elapsed time since the specified start to the histogram in the specified unit of time
@@ -349,13 +532,57 @@ func (histogram *MetricsHistogram) RecordDuration(start time.Time, unit time.Dur
 	elapsedSeconds := float64(since.Seconds())
 	elapsedUnits := float64(since.Truncate(unit))
 
-	histogram.hist.Observe(elapsedUnits)
-	histogram.sum.Observe(elapsedSeconds * 1000.0)
+	histogram.currentHist().Observe(elapsedUnits)
+	if sum := histogram.currentSum(); sum != nil {
+		sum.Observe(elapsedSeconds * 1000.0)
+	}
+	histogram.touch()
 }
 
 // Observe adds the specified value to the histogram.
 func (histogram *MetricsHistogram) Observe(value float64) {
-	histogram.hist.Observe(value)
+	histogram.currentHist().Observe(value)
+	histogram.touch()
+}
+
+// currentHist re-resolves the collector from metrics.Histograms on every call, rather than closing over the
+// one obtained at construction time, so that a handle kept across a Reset keeps observing into the collector
+// that's actually registered instead of silently observing into a now-unregistered one.
+func (histogram *MetricsHistogram) currentHist() prometheus.Histogram {
+	if histogram.metrics == nil {
+		return histogram.hist
+	}
+	histogram.metrics.histMutex.RLock()
+	h, ok := histogram.metrics.Histograms[histogram.Key]
+	histogram.metrics.histMutex.RUnlock()
+	if ok {
+		return h
+	}
+	return histogram.hist
+}
+
+// currentSum mirrors currentHist for the paired Summary, returning nil if this handle was created without one
+// (e.g. via AddHistogramOnly).
+func (histogram *MetricsHistogram) currentSum() prometheus.Summary {
+	if histogram.sum == nil {
+		return nil
+	}
+	if histogram.metrics == nil {
+		return histogram.sum
+	}
+	histogram.metrics.histMutex.RLock()
+	s, ok := histogram.metrics.Summaries[histogram.Key]
+	histogram.metrics.histMutex.RUnlock()
+	if ok {
+		return s
+	}
+	return histogram.sum
+}
+
+func (histogram *MetricsHistogram) touch() {
+	if histogram.metrics != nil {
+		histogram.metrics.touch(expiryHistogram, histogram.Key, nil, histogram.ttl)
+	}
 }
 
 // RecordTimeElapsed adds the elapsed time since the specified start to the histogram in seconds.
@@ -374,11 +601,81 @@ func (vec *HistogramVec) RecordDuration(start time.Time, unit time.Duration) {
 
 // Observe adds the specified value to the histogram.
 func (vec *HistogramVec) Observe(value float64) {
-	vec.histVec.WithLabelValues(vec.LabelValues...).Observe(value)
+	values := vec.guardedLabelValues()
+	vec.currentVec().WithLabelValues(values...).Observe(value)
+	if vec.metrics != nil {
+		vec.metrics.touch(expiryHistogramVec, vec.Key, values, vec.ttl)
+	}
+}
+
+// currentVec re-resolves the collector from metrics.HistogramVecs on every call, rather than closing over the
+// one obtained at construction time, so that a handle kept across a Reset keeps observing into the collector
+// that's actually registered instead of silently observing into a now-unregistered one.
+func (vec *HistogramVec) currentVec() *prometheus.HistogramVec {
+	if vec.metrics == nil {
+		return vec.histVec
+	}
+	vec.metrics.histVecMutex.RLock()
+	v, ok := vec.metrics.HistogramVecs[vec.Key]
+	vec.metrics.histVecMutex.RUnlock()
+	if ok {
+		return v
+	}
+	return vec.histVec
+}
+
+func (vec *HistogramVec) guardedLabelValues() []string {
+	if vec.metrics == nil {
+		return vec.LabelValues
+	}
+	max := vec.maxCardinality
+	if max == 0 {
+		max = vec.metrics.MaxCardinality
+	}
+	overflow := vec.overflowValue
+	if overflow == "" {
+		overflow = vec.metrics.OverflowValue
+	}
+	return vec.metrics.guardCardinality(vec.Key, vec.Labels, vec.LabelValues, max, overflow)
 }
 
 // RecordTimeElapsed adds the elapsed time since the specified start to the summary in milliseconds.
 func (vec *SummaryVec) RecordTimeElapsed(start time.Time) {
 	elapsed := float64(time.Since(start).Seconds())
-	vec.summaryVec.WithLabelValues(vec.LabelValues...).Observe(elapsed * 1000.0) // Summaries are in milliseconds
+	values := vec.guardedLabelValues()
+	vec.currentVec().WithLabelValues(values...).Observe(elapsed * 1000.0) // Summaries are in milliseconds
+	if vec.metrics != nil {
+		vec.metrics.touch(expirySummaryVec, vec.Key, values, vec.ttl)
+	}
+}
+
+// currentVec re-resolves the collector from metrics.SummaryVecs on every call, rather than closing over the
+// one obtained at construction time, so that a handle kept across a Reset keeps observing into the collector
+// that's actually registered instead of silently observing into a now-unregistered one.
+func (vec *SummaryVec) currentVec() *prometheus.SummaryVec {
+	if vec.metrics == nil {
+		return vec.summaryVec
+	}
+	vec.metrics.summaryVecMutex.RLock()
+	v, ok := vec.metrics.SummaryVecs[vec.Key]
+	vec.metrics.summaryVecMutex.RUnlock()
+	if ok {
+		return v
+	}
+	return vec.summaryVec
+}
+
+func (vec *SummaryVec) guardedLabelValues() []string {
+	if vec.metrics == nil {
+		return vec.LabelValues
+	}
+	max := vec.maxCardinality
+	if max == 0 {
+		max = vec.metrics.MaxCardinality
+	}
+	overflow := vec.overflowValue
+	if overflow == "" {
+		overflow = vec.metrics.OverflowValue
+	}
+	return vec.metrics.guardCardinality(vec.Key, vec.Labels, vec.LabelValues, max, overflow)
 }
@@ -0,0 +1,50 @@
+package metrics // white-box test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Travix-International/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics_AddNativeHistogram(t *testing.T) {
+	log, _ := logger.New(make(map[string]string))
+	sut := NewMetricsWithRegistry("ns", log, prometheus.NewRegistry())
+	start := time.Now().Add(+1 * time.Second)
+
+	hist := sut.AddNativeHistogram("native", "hist", "native hist", NativeHistogramOptions{})
+
+	hist.RecordTimeElapsed(start)
+	hist.RecordDuration(start, time.Millisecond)
+	hist.Observe(1.5)
+
+	assert.Equal(t, "native/hist", hist.Key)
+	assert.Equal(t, 1, len(sut.NativeHistograms))
+}
+
+func TestMetrics_AddNativeHistogramVec(t *testing.T) {
+	log, _ := logger.New(make(map[string]string))
+	sut := NewMetricsWithRegistry("ns", log, prometheus.NewRegistry())
+	labels := []string{"label1"}
+	values := []string{"val1"}
+
+	vec := sut.AddNativeHistogramVec("native", "histvec", "native hist vec", labels, values, NativeHistogramOptions{})
+	vec.Observe(2.5)
+
+	assert.Equal(t, "native/histvec", vec.Key)
+	assert.Equal(t, 1, len(sut.NativeHistogramVecs))
+}
+
+func TestMetrics_AddHistogramOnly(t *testing.T) {
+	log, _ := logger.New(make(map[string]string))
+	sut := NewMetricsWithRegistry("ns", log, prometheus.NewRegistry())
+
+	hist := sut.AddHistogramOnly("histonly", "h", "histogram only", []float64{1, 2, 3})
+	hist.Observe(1)
+
+	assert.Equal(t, "histonly/h", hist.Key)
+	assert.Equal(t, 1, len(sut.Histograms))
+	assert.Equal(t, 0, len(sut.Summaries))
+}
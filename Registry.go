@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/Travix-International/logger"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// NewMetricsWithRegistry instantiates a new Metrics wrapper that registers
+// every metric with reg instead of prometheus.DefaultRegisterer. This keeps
+// the wrapper testable in parallel (no need to reset the global registerer
+// between tests) and lets a single process expose more than one disjoint
+// metrics namespace via Handler.
+func NewMetricsWithRegistry(namespace string, logger *logger.Logger, reg *prometheus.Registry) *Metrics {
+	m := NewMetrics(namespace, logger)
+	m.Registerer = reg
+	return m
+}
+
+// Handler returns an http.Handler that serves the metrics registered on
+// m.Registerer. If Registerer is not a *prometheus.Registry (e.g. a custom
+// Registerer implementation), it silently falls back to serving
+// prometheus.DefaultGatherer instead of m.Registerer's own metrics; pass a
+// *prometheus.Registry via NewMetricsWithRegistry to avoid that mismatch.
+func (m *Metrics) Handler() http.Handler {
+	reg, ok := m.Registerer.(*prometheus.Registry)
+	if !ok {
+		reg = prometheus.DefaultGatherer.(*prometheus.Registry)
+	}
+	return promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
+}
+
+// Gather is a passthrough to m.Registerer's Gather method, for callers that
+// want to collect metric families without going through an HTTP handler.
+func (m *Metrics) Gather() ([]*dto.MetricFamily, error) {
+	gatherer, ok := m.Registerer.(prometheus.Gatherer)
+	if !ok {
+		gatherer = prometheus.DefaultGatherer
+	}
+	return gatherer.Gather()
+}
+
+func (m *Metrics) register(c prometheus.Collector) error {
+	return m.Registerer.Register(c)
+}
+
+func (m *Metrics) mustRegister(c prometheus.Collector) {
+	if err := m.Registerer.Register(c); err != nil {
+		panic(err)
+	}
+}
+
+func (m *Metrics) unregister(c prometheus.Collector) {
+	m.Registerer.Unregister(c)
+}
+
+// registerHistogramOrExisting registers h, returning the already-registered collector instead of panicking if
+// the registry already holds a collector with the same fully-qualified name (e.g. the caller's own map entry
+// was evicted and re-created while something else still references the old descriptor). Callers that hold
+// their map's mutex across this call have already ruled out a same-map race, so a collision here means the
+// existing collector came from elsewhere in the registry; it is still returned as-is rather than panicking, to
+// keep the registry free of duplicate registration errors.
+func (m *Metrics) registerHistogramOrExisting(h prometheus.Histogram) prometheus.Histogram {
+	if err := m.Registerer.Register(h); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			return are.ExistingCollector.(prometheus.Histogram)
+		}
+		panic(err)
+	}
+	return h
+}
+
+// registerHistogramVecOrExisting mirrors registerHistogramOrExisting for *prometheus.HistogramVec; it is shared
+// by the classic and native histogram vec constructors, which both produce the same underlying type.
+func (m *Metrics) registerHistogramVecOrExisting(v *prometheus.HistogramVec) *prometheus.HistogramVec {
+	if err := m.Registerer.Register(v); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			return are.ExistingCollector.(*prometheus.HistogramVec)
+		}
+		panic(err)
+	}
+	return v
+}
+
+// registerSummaryVecOrExisting mirrors registerHistogramOrExisting for *prometheus.SummaryVec.
+func (m *Metrics) registerSummaryVecOrExisting(v *prometheus.SummaryVec) *prometheus.SummaryVec {
+	if err := m.Registerer.Register(v); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			return are.ExistingCollector.(*prometheus.SummaryVec)
+		}
+		panic(err)
+	}
+	return v
+}
@@ -0,0 +1,28 @@
+package metrics // white-box test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Travix-International/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics_NewMetricsWithRegistry(t *testing.T) {
+	log, _ := logger.New(make(map[string]string))
+	reg := prometheus.NewRegistry()
+	sut := NewMetricsWithRegistry("ns", log, reg)
+
+	sut.Count("registry", "counter", "registry counter")
+
+	families, err := reg.Gather()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, families)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	sut.Handler().ServeHTTP(rec, req)
+	assert.Equal(t, 200, rec.Code)
+	assert.Contains(t, rec.Body.String(), "ns_registry_counter")
+}
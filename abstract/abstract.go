@@ -0,0 +1,41 @@
+// Package abstract provides backend-agnostic metric interfaces, borrowed
+// from the go-kit metrics package. Code that depends only on these
+// interfaces can swap its metrics backend (Prometheus, StatsD, expvar, ...)
+// via configuration, without importing prometheus into hot paths that don't
+// need it.
+package abstract
+
+// Counter accumulates a monotonic value. With binds it to the specified
+// label values and returns a Counter scoped to that label tuple; it is a
+// no-op when the underlying metric has no labels.
+type Counter interface {
+	With(labelValues ...string) Counter
+	Add(delta float64)
+}
+
+// Gauge reports a point-in-time value that can go up or down. With binds it
+// to the specified label values and returns a Gauge scoped to that tuple.
+type Gauge interface {
+	With(labelValues ...string) Gauge
+	Set(value float64)
+	Add(delta float64)
+}
+
+// Histogram observes a distribution of values. With binds it to the
+// specified label values and returns a Histogram scoped to that tuple.
+type Histogram interface {
+	With(labelValues ...string) Histogram
+	Observe(value float64)
+}
+
+// Provider creates Counters, Gauges and Histograms for a given backend.
+// Every New* method is idempotent: calling it twice with the same
+// subsystem/name returns handles to the same underlying metric.
+type Provider interface {
+	NewCounter(subsystem, name, help string) Counter
+	NewGauge(subsystem, name, help string) Gauge
+	NewHistogram(subsystem, name, help string, buckets []float64) Histogram
+	NewCounterVec(subsystem, name, help string, labels []string) Counter
+	NewGaugeVec(subsystem, name, help string, labels []string) Gauge
+	NewHistogramVec(subsystem, name, help string, buckets []float64, labels []string) Histogram
+}
@@ -0,0 +1,69 @@
+package abstract_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/Travix-International/logger"
+	"github.com/stretchr/testify/assert"
+
+	gometrics "github.com/Travix-International/go-metrics"
+	"github.com/Travix-International/go-metrics/abstract"
+)
+
+func TestPrometheusProvider_CounterVec(t *testing.T) {
+	log, _ := logger.New(make(map[string]string))
+	m := gometrics.NewMetrics("ns", log)
+	provider := abstract.NewPrometheusProvider(m)
+
+	counter := provider.NewCounterVec("sub", "requests", "total requests", []string{"method"})
+	counter.With("GET").Add(1)
+
+	// calling it again for the same subsystem/name must not panic on
+	// duplicate registration.
+	again := provider.NewCounterVec("sub", "requests", "total requests", []string{"method"})
+	again.With("POST").Add(1)
+}
+
+func TestPrometheusProvider_CounterWithIsNoOpWithoutLabels(t *testing.T) {
+	log, _ := logger.New(make(map[string]string))
+	m := gometrics.NewMetrics("ns", log)
+	provider := abstract.NewPrometheusProvider(m)
+
+	counter := provider.NewCounter("sub", "total", "total")
+
+	// With on a label-less counter must be a no-op per the Counter doc
+	// comment, not pass the values to a zero-label vector (which would
+	// panic with "inconsistent label cardinality").
+	counter.With("unexpected").Add(1)
+}
+
+func TestExpvarProvider_Histogram(t *testing.T) {
+	provider := abstract.NewExpvarProvider()
+
+	hist := provider.NewHistogramVec("sub", "latency", "latency", nil, []string{"route"})
+	bound := hist.With("/health")
+	bound.Observe(1.5)
+	bound.Observe(2.5)
+}
+
+func TestStatsDProvider_Gauge(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+
+	provider := abstract.NewStatsDProvider(client)
+	gauge := provider.NewGauge("sub", "queue_depth", "queue depth")
+
+	done := make(chan string)
+	go func() {
+		buf := make([]byte, 256)
+		n, _ := server.Read(buf)
+		done <- string(buf[:n])
+	}()
+
+	gauge.Set(42)
+
+	line := <-done
+	assert.Equal(t, "sub.queue_depth:42|g\n", line)
+}
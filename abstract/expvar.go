@@ -0,0 +1,144 @@
+package abstract
+
+import (
+	"expvar"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ExpvarProvider publishes metrics through the standard library's expvar
+// package, for environments that can't run a Prometheus scrape or a statsd
+// agent (e.g. inspecting a short-lived job via /debug/vars). It has no
+// concept of quantiles, so Histogram.Observe only keeps a running count/sum,
+// exposed as "<bucket>.count" and "<bucket>.sum".
+type ExpvarProvider struct{}
+
+// expvar.Publish panics with "Reuse of exported var name" if the same key is
+// ever published twice in one process, which happens whenever two
+// ExpvarProvider instances (e.g. one per Metrics in a multi-tenant process,
+// see NewMetricsWithRegistry) share a subsystem/name. expvar.Vars have no
+// process-wide registry to look a var up by name, so dedup has to live here,
+// keyed the same way across every ExpvarProvider.
+var (
+	expvarMu   sync.Mutex
+	expvarInts = make(map[string]*expvar.Int)
+	expvarFlts = make(map[string]*expvar.Float)
+)
+
+// NewExpvarProvider returns a Provider that registers its metrics in the
+// default expvar map the first time each one is created.
+func NewExpvarProvider() *ExpvarProvider {
+	return &ExpvarProvider{}
+}
+
+func (p *ExpvarProvider) NewCounter(subsystem, name, help string) Counter {
+	return p.NewCounterVec(subsystem, name, help, nil)
+}
+
+func (p *ExpvarProvider) NewCounterVec(subsystem, name, help string, labels []string) Counter {
+	return &expvarCounter{provider: p, bucket: bucketName(subsystem, name), labels: labels}
+}
+
+func (p *ExpvarProvider) NewGauge(subsystem, name, help string) Gauge {
+	return p.NewGaugeVec(subsystem, name, help, nil)
+}
+
+func (p *ExpvarProvider) NewGaugeVec(subsystem, name, help string, labels []string) Gauge {
+	return &expvarGauge{provider: p, bucket: bucketName(subsystem, name), labels: labels}
+}
+
+func (p *ExpvarProvider) NewHistogram(subsystem, name, help string, buckets []float64) Histogram {
+	return p.NewHistogramVec(subsystem, name, help, buckets, nil)
+}
+
+func (p *ExpvarProvider) NewHistogramVec(subsystem, name, help string, buckets []float64, labels []string) Histogram {
+	return &expvarHistogram{provider: p, bucket: bucketName(subsystem, name), labels: labels}
+}
+
+func (p *ExpvarProvider) intVar(key string) *expvar.Int {
+	expvarMu.Lock()
+	defer expvarMu.Unlock()
+	v, ok := expvarInts[key]
+	if !ok {
+		v = expvar.NewInt(key)
+		expvarInts[key] = v
+	}
+	return v
+}
+
+func (p *ExpvarProvider) floatVar(key string) *expvar.Float {
+	expvarMu.Lock()
+	defer expvarMu.Unlock()
+	v, ok := expvarFlts[key]
+	if !ok {
+		v = expvar.NewFloat(key)
+		expvarFlts[key] = v
+	}
+	return v
+}
+
+func boundKey(bucket string, labels, labelValues []string) string {
+	if len(labels) == 0 || len(labelValues) == 0 {
+		return bucket
+	}
+	tags := make([]string, 0, len(labels))
+	for i, label := range labels {
+		if i >= len(labelValues) {
+			break
+		}
+		tags = append(tags, fmt.Sprintf("%s=%s", label, labelValues[i]))
+	}
+	return fmt.Sprintf("%s{%s}", bucket, strings.Join(tags, ","))
+}
+
+type expvarCounter struct {
+	provider    *ExpvarProvider
+	bucket      string
+	labels      []string
+	labelValues []string
+}
+
+func (c *expvarCounter) With(labelValues ...string) Counter {
+	return &expvarCounter{provider: c.provider, bucket: c.bucket, labels: c.labels, labelValues: labelValues}
+}
+
+func (c *expvarCounter) Add(delta float64) {
+	c.provider.intVar(boundKey(c.bucket, c.labels, c.labelValues)).Add(int64(delta))
+}
+
+type expvarGauge struct {
+	provider    *ExpvarProvider
+	bucket      string
+	labels      []string
+	labelValues []string
+}
+
+func (g *expvarGauge) With(labelValues ...string) Gauge {
+	return &expvarGauge{provider: g.provider, bucket: g.bucket, labels: g.labels, labelValues: labelValues}
+}
+
+func (g *expvarGauge) Set(value float64) {
+	g.provider.floatVar(boundKey(g.bucket, g.labels, g.labelValues)).Set(value)
+}
+
+func (g *expvarGauge) Add(delta float64) {
+	g.provider.floatVar(boundKey(g.bucket, g.labels, g.labelValues)).Add(delta)
+}
+
+type expvarHistogram struct {
+	provider    *ExpvarProvider
+	bucket      string
+	labels      []string
+	labelValues []string
+}
+
+func (h *expvarHistogram) With(labelValues ...string) Histogram {
+	return &expvarHistogram{provider: h.provider, bucket: h.bucket, labels: h.labels, labelValues: labelValues}
+}
+
+func (h *expvarHistogram) Observe(value float64) {
+	key := boundKey(h.bucket, h.labels, h.labelValues)
+	h.provider.intVar(key + ".count").Add(1)
+	h.provider.floatVar(key + ".sum").Add(value)
+}
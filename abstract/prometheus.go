@@ -0,0 +1,193 @@
+package abstract
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	gometrics "github.com/Travix-International/go-metrics"
+)
+
+// PrometheusProvider adapts an existing *gometrics.Metrics wrapper to the
+// Provider interface, registering every metric on the same Registerer (and
+// under the same Namespace) as m.
+type PrometheusProvider struct {
+	metrics *gometrics.Metrics
+}
+
+// NewPrometheusProvider returns a Provider backed by m.
+func NewPrometheusProvider(m *gometrics.Metrics) *PrometheusProvider {
+	return &PrometheusProvider{metrics: m}
+}
+
+func (p *PrometheusProvider) NewCounter(subsystem, name, help string) Counter {
+	return p.NewCounterVec(subsystem, name, help, nil)
+}
+
+func (p *PrometheusProvider) NewCounterVec(subsystem, name, help string, labels []string) Counter {
+	vec := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: p.metrics.Namespace,
+		Subsystem: subsystem,
+		Name:      name,
+		Help:      help,
+	}, labels)
+	return &prometheusCounter{
+		metrics: p.metrics,
+		key:     fmt.Sprintf("%s/%s", subsystem, name),
+		labels:  labels,
+		vec:     registerCounterVec(p.metrics, vec),
+	}
+}
+
+func (p *PrometheusProvider) NewGauge(subsystem, name, help string) Gauge {
+	return p.NewGaugeVec(subsystem, name, help, nil)
+}
+
+func (p *PrometheusProvider) NewGaugeVec(subsystem, name, help string, labels []string) Gauge {
+	vec := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: p.metrics.Namespace,
+		Subsystem: subsystem,
+		Name:      name,
+		Help:      help,
+	}, labels)
+	return &prometheusGauge{
+		metrics: p.metrics,
+		key:     fmt.Sprintf("%s/%s", subsystem, name),
+		labels:  labels,
+		vec:     registerGaugeVec(p.metrics, vec),
+	}
+}
+
+func (p *PrometheusProvider) NewHistogram(subsystem, name, help string, buckets []float64) Histogram {
+	return p.NewHistogramVec(subsystem, name, help, buckets, nil)
+}
+
+func (p *PrometheusProvider) NewHistogramVec(subsystem, name, help string, buckets []float64, labels []string) Histogram {
+	vec := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: p.metrics.Namespace,
+		Subsystem: subsystem,
+		Name:      name,
+		Help:      help,
+		Buckets:   buckets,
+	}, labels)
+	return &prometheusHistogram{
+		metrics: p.metrics,
+		key:     fmt.Sprintf("%s/%s", subsystem, name),
+		labels:  labels,
+		vec:     registerHistogramVec(p.metrics, vec),
+	}
+}
+
+// registerCounterVec registers vec on m.Registerer, returning the already
+// registered collector instead when vec is a duplicate registration.
+func registerCounterVec(m *gometrics.Metrics, vec *prometheus.CounterVec) *prometheus.CounterVec {
+	if err := m.Registerer.Register(vec); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			return are.ExistingCollector.(*prometheus.CounterVec)
+		}
+		panic(fmt.Sprintf("abstract: counter registration failed: %v", err))
+	}
+	return vec
+}
+
+func registerGaugeVec(m *gometrics.Metrics, vec *prometheus.GaugeVec) *prometheus.GaugeVec {
+	if err := m.Registerer.Register(vec); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			return are.ExistingCollector.(*prometheus.GaugeVec)
+		}
+		panic(fmt.Sprintf("abstract: gauge registration failed: %v", err))
+	}
+	return vec
+}
+
+func registerHistogramVec(m *gometrics.Metrics, vec *prometheus.HistogramVec) *prometheus.HistogramVec {
+	if err := m.Registerer.Register(vec); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			return are.ExistingCollector.(*prometheus.HistogramVec)
+		}
+		panic(fmt.Sprintf("abstract: histogram registration failed: %v", err))
+	}
+	return vec
+}
+
+type prometheusCounter struct {
+	metrics     *gometrics.Metrics
+	key         string
+	labels      []string
+	vec         *prometheus.CounterVec
+	labelValues []string
+}
+
+func (c *prometheusCounter) With(labelValues ...string) Counter {
+	if len(c.labels) == 0 {
+		return c
+	}
+	return &prometheusCounter{metrics: c.metrics, key: c.key, labels: c.labels, vec: c.vec, labelValues: labelValues}
+}
+
+func (c *prometheusCounter) Add(delta float64) {
+	c.vec.WithLabelValues(c.guardedLabelValues()...).Add(delta)
+}
+
+func (c *prometheusCounter) guardedLabelValues() []string {
+	if len(c.labels) == 0 {
+		return nil
+	}
+	return c.metrics.GuardLabelValues(c.key, c.labels, c.labelValues)
+}
+
+type prometheusGauge struct {
+	metrics     *gometrics.Metrics
+	key         string
+	labels      []string
+	vec         *prometheus.GaugeVec
+	labelValues []string
+}
+
+func (g *prometheusGauge) With(labelValues ...string) Gauge {
+	if len(g.labels) == 0 {
+		return g
+	}
+	return &prometheusGauge{metrics: g.metrics, key: g.key, labels: g.labels, vec: g.vec, labelValues: labelValues}
+}
+
+func (g *prometheusGauge) Set(value float64) {
+	g.vec.WithLabelValues(g.guardedLabelValues()...).Set(value)
+}
+
+func (g *prometheusGauge) Add(delta float64) {
+	g.vec.WithLabelValues(g.guardedLabelValues()...).Add(delta)
+}
+
+func (g *prometheusGauge) guardedLabelValues() []string {
+	if len(g.labels) == 0 {
+		return nil
+	}
+	return g.metrics.GuardLabelValues(g.key, g.labels, g.labelValues)
+}
+
+type prometheusHistogram struct {
+	metrics     *gometrics.Metrics
+	key         string
+	labels      []string
+	vec         *prometheus.HistogramVec
+	labelValues []string
+}
+
+func (h *prometheusHistogram) With(labelValues ...string) Histogram {
+	if len(h.labels) == 0 {
+		return h
+	}
+	return &prometheusHistogram{metrics: h.metrics, key: h.key, labels: h.labels, vec: h.vec, labelValues: labelValues}
+}
+
+func (h *prometheusHistogram) Observe(value float64) {
+	h.vec.WithLabelValues(h.guardedLabelValues()...).Observe(value)
+}
+
+func (h *prometheusHistogram) guardedLabelValues() []string {
+	if len(h.labels) == 0 {
+		return nil
+	}
+	return h.metrics.GuardLabelValues(h.key, h.labels, h.labelValues)
+}
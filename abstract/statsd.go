@@ -0,0 +1,132 @@
+package abstract
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// StatsDProvider writes statsd line protocol to conn, one UDP/TCP packet per
+// observation. Plain statsd has no concept of labels, so label values are
+// appended as DogStatsD-style "|#tag:value" tags, which most modern statsd
+// agents (and relays in front of Prometheus, per the statsd_exporter this
+// package's TTL design was borrowed from) understand.
+type StatsDProvider struct {
+	conn net.Conn
+	mu   *sync.Mutex
+}
+
+// NewStatsDProvider returns a Provider that writes to conn. Callers own
+// conn's lifecycle (dialing and closing it).
+func NewStatsDProvider(conn net.Conn) *StatsDProvider {
+	return &StatsDProvider{conn: conn, mu: &sync.Mutex{}}
+}
+
+func (p *StatsDProvider) NewCounter(subsystem, name, help string) Counter {
+	return p.NewCounterVec(subsystem, name, help, nil)
+}
+
+func (p *StatsDProvider) NewCounterVec(subsystem, name, help string, labels []string) Counter {
+	return &statsdCounter{core: newStatsDCore(p, subsystem, name, "c", labels)}
+}
+
+func (p *StatsDProvider) NewGauge(subsystem, name, help string) Gauge {
+	return p.NewGaugeVec(subsystem, name, help, nil)
+}
+
+func (p *StatsDProvider) NewGaugeVec(subsystem, name, help string, labels []string) Gauge {
+	return &statsdGauge{core: newStatsDCore(p, subsystem, name, "g", labels)}
+}
+
+func (p *StatsDProvider) NewHistogram(subsystem, name, help string, buckets []float64) Histogram {
+	return p.NewHistogramVec(subsystem, name, help, buckets, nil)
+}
+
+func (p *StatsDProvider) NewHistogramVec(subsystem, name, help string, buckets []float64, labels []string) Histogram {
+	// statsd has no concept of pre-declared buckets: the agent/aggregator
+	// computes quantiles from the raw observations, so buckets is unused.
+	return &statsdHistogram{core: newStatsDCore(p, subsystem, name, "h", labels)}
+}
+
+// statsdCore holds what every statsd-backed metric needs: the provider to
+// write through, the metric's bucket name, its statsd type suffix, and the
+// label names/values currently bound to it.
+type statsdCore struct {
+	provider    *StatsDProvider
+	bucket      string
+	suffix      string
+	labels      []string
+	labelValues []string
+}
+
+func newStatsDCore(p *StatsDProvider, subsystem, name, suffix string, labels []string) *statsdCore {
+	return &statsdCore{provider: p, bucket: bucketName(subsystem, name), suffix: suffix, labels: labels}
+}
+
+func (c *statsdCore) with(labelValues []string) *statsdCore {
+	return &statsdCore{provider: c.provider, bucket: c.bucket, suffix: c.suffix, labels: c.labels, labelValues: labelValues}
+}
+
+func (c *statsdCore) write(line string) {
+	c.provider.mu.Lock()
+	defer c.provider.mu.Unlock()
+	fmt.Fprint(c.provider.conn, line)
+}
+
+func (c *statsdCore) line(value string) string {
+	line := fmt.Sprintf("%s:%s|%s", c.bucket, value, c.suffix)
+	if len(c.labels) > 0 && len(c.labelValues) > 0 {
+		tags := make([]string, 0, len(c.labels))
+		for i, label := range c.labels {
+			if i >= len(c.labelValues) {
+				break
+			}
+			tags = append(tags, fmt.Sprintf("%s:%s", label, c.labelValues[i]))
+		}
+		line += "|#" + strings.Join(tags, ",")
+	}
+	return line + "\n"
+}
+
+func bucketName(subsystem, name string) string {
+	return subsystem + "." + name
+}
+
+type statsdCounter struct{ core *statsdCore }
+
+func (c *statsdCounter) With(labelValues ...string) Counter {
+	return &statsdCounter{core: c.core.with(labelValues)}
+}
+
+func (c *statsdCounter) Add(delta float64) {
+	c.core.write(c.core.line(fmt.Sprintf("%g", delta)))
+}
+
+type statsdGauge struct{ core *statsdCore }
+
+func (g *statsdGauge) With(labelValues ...string) Gauge {
+	return &statsdGauge{core: g.core.with(labelValues)}
+}
+
+func (g *statsdGauge) Set(value float64) {
+	g.core.write(g.core.line(fmt.Sprintf("%g", value)))
+}
+
+func (g *statsdGauge) Add(delta float64) {
+	sign := "+"
+	if delta < 0 {
+		sign = ""
+	}
+	g.core.write(g.core.line(fmt.Sprintf("%s%g", sign, delta)))
+}
+
+type statsdHistogram struct{ core *statsdCore }
+
+func (h *statsdHistogram) With(labelValues ...string) Histogram {
+	return &statsdHistogram{core: h.core.with(labelValues)}
+}
+
+func (h *statsdHistogram) Observe(value float64) {
+	h.core.write(h.core.line(fmt.Sprintf("%g", value)))
+}